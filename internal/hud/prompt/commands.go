@@ -0,0 +1,179 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+const (
+	waitHealthyTimeout      = 2 * time.Minute
+	waitHealthyPollInterval = 250 * time.Millisecond
+)
+
+// commandFunc implements a single prompt command, shared between the
+// interactive rune handler in listen() and the scripted evaluator used by
+// RunScript/--prompt-exec. args is always empty for rune-triggered
+// commands, since a single keypress can't carry arguments.
+type commandFunc func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error
+
+// command is one entry in the prompt's command registry. `e` (shell out)
+// and `r` (resource picker) aren't registered here: they need to pause and
+// resume the goroutine that owns the TTY, which doesn't fit the
+// synchronous, TTY-free shape every other command has.
+type command struct {
+	// Name is how the command is spelled in a Script line, e.g. "trigger".
+	Name string
+	// Rune, if non-zero, is the key that triggers this command
+	// interactively. Commands that take arguments have no rune binding and
+	// are only reachable from a Script.
+	Rune rune
+	// SwitchesMode is true if running this command means the interactive
+	// loop's TTY reader goroutine should stop (because we're leaving the
+	// prompt for another terminal mode).
+	SwitchesMode bool
+	Run          commandFunc
+}
+
+var commandRegistry = []command{
+	{
+		Name:         "stream",
+		Rune:         's',
+		SwitchesMode: true,
+		Run: func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error {
+			st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeStream})
+			return nil
+		},
+	},
+	{
+		Name:         "hud",
+		Rune:         'h',
+		SwitchesMode: true,
+		Run: func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error {
+			st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeHUD})
+			return nil
+		},
+	},
+	{
+		Name: "open-browser",
+		Rune: ' ',
+		Run: func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error {
+			_, _ = fmt.Fprintf(p.stdout, "Opening browser: %s\n", p.url.String())
+			return p.openURL(p.url.String())
+		},
+	},
+	{
+		Name: "trigger",
+		Run: func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected exactly one resource name, got %v", args)
+			}
+			st.Dispatch(store.AppendToTriggerQueueAction{
+				Name:   model.ManifestName(args[0]),
+				Reason: model.BuildReasonFlagTriggerWeb,
+			})
+			_, _ = fmt.Fprintf(p.stdout, "Queued rebuild: %s\n", args[0])
+			return nil
+		},
+	},
+	{
+		Name: "wait-healthy",
+		Run: func(ctx context.Context, p *TerminalPrompt, st store.RStore, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected exactly one resource name, got %v", args)
+			}
+			name := model.ManifestName(args[0])
+
+			ticker := time.NewTicker(waitHealthyPollInterval)
+			defer ticker.Stop()
+			deadline := time.After(waitHealthyTimeout)
+
+			for {
+				if isHealthy(st, name) {
+					_, _ = fmt.Fprintf(p.stdout, "Healthy: %s\n", name)
+					return nil
+				}
+
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-deadline:
+					return fmt.Errorf("%s did not become healthy within %s", name, waitHealthyTimeout)
+				case <-ticker.C:
+				}
+			}
+		},
+	},
+}
+
+func isHealthy(st store.RStore, name model.ManifestName) bool {
+	state := st.RLockState()
+	defer st.RUnlockState()
+	for _, mt := range state.ManifestTargets {
+		if mt.Manifest.Name == name {
+			return mt.State.RuntimeState.RuntimeStatus() == model.RuntimeStatusOK
+		}
+	}
+	return false
+}
+
+var commandsByRune = func() map[rune]*command {
+	m := make(map[rune]*command, len(commandRegistry))
+	for i := range commandRegistry {
+		if r := commandRegistry[i].Rune; r != 0 {
+			m[r] = &commandRegistry[i]
+		}
+	}
+	return m
+}()
+
+var commandsByName = func() map[string]*command {
+	m := make(map[string]*command, len(commandRegistry))
+	for i := range commandRegistry {
+		m[commandRegistry[i].Name] = &commandRegistry[i]
+	}
+	return m
+}()
+
+// scriptAliases lets a Script use the same short spellings as the
+// interactive rune bindings (e.g. "s" instead of "stream"), since that's
+// what most --prompt-exec scripts will read like.
+var scriptAliases = map[string]string{
+	"s":     "stream",
+	"h":     "hud",
+	"space": "open-browser",
+}
+
+// RunScript evaluates p.Script against the command registry, one line at a
+// time, without ever opening a TTY. It's meant for CI smoke tests and
+// headless demos that still want to exercise the prompt's dispatch logic:
+// see TerminalPrompt.Script. It returns the first error encountered,
+// which includes a failed wait-healthy assertion.
+func (p *TerminalPrompt) RunScript(ctx context.Context, st store.RStore) error {
+	for _, line := range p.Script {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		name, args := fields[0], fields[1:]
+		if alias, ok := scriptAliases[name]; ok {
+			name = alias
+		}
+
+		cmd, ok := commandsByName[name]
+		if !ok {
+			return fmt.Errorf("prompt-exec: unrecognized command %q", line)
+		}
+
+		_, _ = fmt.Fprintf(p.stdout, "$ %s\n", line)
+		if err := cmd.Run(ctx, p, st, args); err != nil {
+			return fmt.Errorf("prompt-exec: %q failed: %v", line, err)
+		}
+	}
+	return nil
+}
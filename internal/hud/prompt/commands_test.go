@@ -0,0 +1,44 @@
+package prompt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandRegistryNamesAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range commandRegistry {
+		assert.False(t, seen[c.Name], "duplicate command name %q", c.Name)
+		seen[c.Name] = true
+	}
+}
+
+func TestCommandsByRuneMatchesRegistry(t *testing.T) {
+	for _, c := range commandRegistry {
+		if c.Rune == 0 {
+			continue
+		}
+		found, ok := commandsByRune[c.Rune]
+		if assert.True(t, ok, "rune %q missing from commandsByRune", c.Rune) {
+			assert.Equal(t, c.Name, found.Name)
+		}
+	}
+}
+
+func TestScriptAliasesResolveToRegisteredCommands(t *testing.T) {
+	for alias, name := range scriptAliases {
+		_, ok := commandsByName[name]
+		assert.True(t, ok, "alias %q points at unregistered command %q", alias, name)
+	}
+}
+
+func TestRunScriptRejectsUnrecognizedCommand(t *testing.T) {
+	p := &TerminalPrompt{stdout: &bytes.Buffer{}, Script: []string{"not-a-real-command"}}
+
+	// Neither ctx nor st is touched on the error path being tested: RunScript
+	// fails the lookup before it would ever call cmd.Run.
+	err := p.RunScript(nil, nil) //nolint:staticcheck
+	assert.ErrorContains(t, err, "unrecognized command")
+}
@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
+	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	tty "github.com/mattn/go-tty"
@@ -28,30 +32,69 @@ func TTYOpen() (TerminalInput, error) {
 	return tty.Open()
 }
 
+// ShellCommand builds the command to run when the user asks to shell out
+// from the prompt. It inherits the parent's stdin/stdout/stderr so the
+// subprocess (an editor, `kubectl exec`, etc.) can take over the terminal.
+type ShellCommand func() *exec.Cmd
+
+// DefaultShell shells out to $SHELL (or /bin/sh if unset). Most callers
+// will want something more targeted, like `kubectl exec` into the
+// currently-selected resource, but this is a reasonable fallback.
+func DefaultShell() *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return exec.Command(shell)
+}
+
 func BrowserOpen(url string) error {
 	return browser.OpenURL(url)
 }
 
 type TerminalPrompt struct {
-	openInput  OpenInput
-	openURL    OpenURL
-	stdout     hud.Stdout
-	host       model.WebHost
-	url        model.WebURL
-	printed    bool
-	initOutput *bytes.Buffer
+	openInput    OpenInput
+	openURL      OpenURL
+	shellCommand ShellCommand
+	stdout       hud.Stdout
+	host         model.WebHost
+	url          model.WebURL
+	printed      bool
+	initOutput   *bytes.Buffer
+
+	// lastStore is stashed on each OnChange so that out-of-band callers
+	// (currently just SharePrompt, for remote viewer key events) have a
+	// store.RStore to dispatch against. OnChange runs on the store's
+	// subscriber-notify goroutine, while reads happen on whatever goroutine
+	// is handling a remote viewer's WebSocket connection, so access is
+	// guarded by lastStoreMu rather than left as a bare field.
+	lastStoreMu sync.Mutex
+	lastStore   store.RStore
+
+	// Script, if non-empty, switches the prompt into non-interactive mode:
+	// OnChange evaluates each line against the command registry via
+	// RunScript instead of ever opening a TTY. No CLI flag sets it; callers
+	// assign it directly.
+	Script []string
 }
 
 func NewTerminalPrompt(openInput OpenInput, openURL OpenURL, stdout hud.Stdout, host model.WebHost, url model.WebURL) *TerminalPrompt {
 	return &TerminalPrompt{
-		openInput: openInput,
-		openURL:   openURL,
-		stdout:    stdout,
-		host:      host,
-		url:       url,
+		openInput:    openInput,
+		openURL:      openURL,
+		shellCommand: DefaultShell,
+		stdout:       stdout,
+		host:         host,
+		url:          url,
 	}
 }
 
+// SetShellCommand overrides the command run when the user presses `e` to
+// shell out of the prompt. Defaults to DefaultShell.
+func (p *TerminalPrompt) SetShellCommand(cmd ShellCommand) {
+	p.shellCommand = cmd
+}
+
 // Copy initial warnings and info logs from the logstore into the terminal
 // prompt, so that they get shown as part of the prompt.
 //
@@ -82,7 +125,25 @@ func (p *TerminalPrompt) isEnabled(st store.RStore) bool {
 	return state.TerminalMode == store.TerminalModePrompt
 }
 
+// setLastStore and lastStoreForDispatch guard lastStore with a mutex: the
+// write happens on the store's subscriber-notify goroutine (OnChange), the
+// read happens on whatever goroutine is servicing a remote viewer's
+// WebSocket connection (SharePrompt.handleRemoteKey).
+func (p *TerminalPrompt) setLastStore(st store.RStore) {
+	p.lastStoreMu.Lock()
+	defer p.lastStoreMu.Unlock()
+	p.lastStore = st
+}
+
+func (p *TerminalPrompt) lastStoreForDispatch() store.RStore {
+	p.lastStoreMu.Lock()
+	defer p.lastStoreMu.Unlock()
+	return p.lastStore
+}
+
 func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
+	p.setLastStore(st)
+
 	if !p.isEnabled(st) {
 		return
 	}
@@ -109,16 +170,18 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 		_, _ = fmt.Fprintf(p.stdout, "%s\n", line)
 	}
 
-	hasBrowserUI := !p.url.Empty()
-	if hasBrowserUI {
-		_, _ = fmt.Fprintf(p.stdout, "(space) to open the browser\n")
-	}
+	p.printed = true
 
-	_, _ = fmt.Fprintf(p.stdout, "(s) to stream logs\n")
-	_, _ = fmt.Fprintf(p.stdout, "(h) to open terminal HUD\n")
-	_, _ = fmt.Fprintf(p.stdout, "(ctrl-c) to exit\n")
+	if len(p.Script) > 0 {
+		// --prompt-exec mode: evaluate the script against the same command
+		// registry the interactive loop uses and never touch a TTY.
+		if err := p.RunScript(ctx, st); err != nil {
+			st.Dispatch(store.ErrorAction{Error: err})
+		}
+		return
+	}
 
-	p.printed = true
+	p.printMenu()
 
 	t, err := p.openInput()
 	if err != nil {
@@ -126,6 +189,30 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 		return
 	}
 
+	p.listen(ctx, st, t)
+}
+
+// printMenu prints the list of key bindings the prompt currently accepts.
+// It's re-printed after the terminal is restored from a ReleaseTerminal,
+// since the subshell/editor that ran in the meantime will have scrolled it
+// off the screen.
+func (p *TerminalPrompt) printMenu() {
+	hasBrowserUI := !p.url.Empty()
+	if hasBrowserUI {
+		_, _ = fmt.Fprintf(p.stdout, "(space) to open the browser\n")
+	}
+
+	_, _ = fmt.Fprintf(p.stdout, "(s) to stream logs\n")
+	_, _ = fmt.Fprintf(p.stdout, "(h) to open terminal HUD\n")
+	_, _ = fmt.Fprintf(p.stdout, "(r) to pick a resource\n")
+	_, _ = fmt.Fprintf(p.stdout, "(e) to shell out\n")
+	_, _ = fmt.Fprintf(p.stdout, "(ctrl-c) to exit\n")
+}
+
+// listen starts the goroutines that read and dispatch on TTY input. It's
+// called both on startup and after RestoreTerminal, since ReleaseTerminal
+// tears the previous pair of goroutines down.
+func (p *TerminalPrompt) listen(ctx context.Context, st store.RStore, t TerminalInput) {
 	keyCh := make(chan runeMessage)
 
 	// One goroutine just pulls input from TTY.
@@ -153,48 +240,187 @@ func (p *TerminalPrompt) OnChange(ctx context.Context, st store.RStore) {
 
 	// Another goroutine processes the input. Doing this
 	// on a separate goroutine allows us to clean up the TTY
-	// even if it's still blocking on the ReadRune
+	// even if it's still blocking on the ReadRune.
+	//
+	// t is only closed here at an actual termination point (ctx done, or
+	// the reader goroutine exiting). The 'r' and 'e' cases below hand t off
+	// instead: the resource picker resumes listen() on the very same t, and
+	// releaseAndRestore closes t itself before opening a fresh TTY, so
+	// closing it on the way out of this goroutine would yank the TTY out
+	// from under whatever's using it next.
 	go func() {
-		defer func() {
-			_ = t.Close()
-		}()
-
 		for ctx.Err() == nil {
 			select {
 			case <-ctx.Done():
+				_ = t.Close()
 				return
 			case msg, ok := <-keyCh:
 				if !ok {
+					_ = t.Close()
 					return
 				}
 
 				r := msg.rune
 				switch r {
-				case 's':
-					st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeStream})
+				case 'r':
+					// The picker reads directly off t, so stop the reader
+					// goroutine first to avoid two readers racing on the TTY.
 					msg.stopCh <- true
+					p.runResourcePicker(ctx, st, t)
+					return
 
-				case 'h':
-					st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeHUD})
+				case 'e':
+					// Stop the reader goroutine before we close its TTY out
+					// from under it, so it doesn't race on ReadRune.
 					msg.stopCh <- true
+					p.releaseAndRestore(ctx, st, t)
+					return
 
-				case ' ':
-					_, _ = fmt.Fprintf(p.stdout, "Opening browser: %s\n", p.url.String())
-					err := p.openURL(p.url.String())
-					if err != nil {
-						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
-					}
-					msg.stopCh <- false
 				default:
-					_, _ = fmt.Fprintf(p.stdout, "Unrecognized option: %s\n", string(r))
-					msg.stopCh <- false
+					cmd, ok := commandsByRune[r]
+					if !ok {
+						_, _ = fmt.Fprintf(p.stdout, "Unrecognized option: %s\n", string(r))
+						msg.stopCh <- false
+						continue
+					}
 
+					if err := cmd.Run(ctx, p, st, nil); err != nil {
+						_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
+					}
+					msg.stopCh <- cmd.SwitchesMode
 				}
 			}
 		}
 	}()
 }
 
+// TerminalReleaseAction is dispatched when the terminal is handed off to a
+// subprocess via the `e` shell-out command (Released: true) and when
+// control has come back to the prompt (Released: false).
+//
+// NOTE: this only defines and dispatches the action. No reducer handles it,
+// so it does not change EngineState and the HUD/stream modes are not told
+// that the terminal is in use.
+type TerminalReleaseAction struct {
+	Released bool
+}
+
+func (TerminalReleaseAction) Action() {}
+
+// releaseAndRestore is the ReleaseTerminal/RestoreTerminal cycle for the
+// `e` key: it closes the TTY reader, restores cooked mode, runs the
+// configured shell command inheriting stdin/stdout/stderr, then re-opens
+// the TTY and resumes listening.
+func (p *TerminalPrompt) releaseAndRestore(ctx context.Context, st store.RStore, t TerminalInput) {
+	st.Dispatch(TerminalReleaseAction{Released: true})
+	_ = t.Close()
+
+	cmd := p.shellCommand()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
+	}
+
+	st.Dispatch(TerminalReleaseAction{Released: false})
+	p.printMenu()
+
+	newT, err := p.openInput()
+	if err != nil {
+		st.Dispatch(store.ErrorAction{Error: err})
+		return
+	}
+
+	if ctx.Err() != nil {
+		_ = newT.Close()
+		return
+	}
+
+	p.listen(ctx, st, newT)
+}
+
+// pickerKeyMessage is the resource-picker analog of runeMessage: it lets
+// the key-reading goroutine hand a key to the picker's event loop and then
+// block (instead of calling ReadRune again) until told whether to keep
+// reading or stop, so t can be safely handed off elsewhere without two
+// goroutines racing on it.
+type pickerKeyMessage struct {
+	key    pickerKey
+	stopCh chan bool
+}
+
+// resourcePickerRedrawInterval is how often the picker polls the store and
+// redraws while idle, so the list reflects builds completing in the
+// background even if the user never presses a key. The prompt package only
+// observes store changes via TerminalPrompt.OnChange, which returns early
+// once the greeting has been printed, so this polls rather than subscribing.
+const resourcePickerRedrawInterval = 500 * time.Millisecond
+
+// runResourcePicker hands the TTY over to a resourcePicker until the user
+// backs out (`q`) or a key handler switches terminal modes (noticed on the
+// next redraw tick, after which t is closed). It owns t
+// directly rather than going through keyCh, since arrow keys need to read
+// ahead for escape sequences.
+func (p *TerminalPrompt) runResourcePicker(ctx context.Context, st store.RStore, t TerminalInput) {
+	rp := newResourcePicker(p)
+	rp.refresh(st)
+	rp.render()
+
+	keyCh := make(chan pickerKeyMessage)
+	errCh := make(chan error, 1)
+
+	go func() {
+		for {
+			key, err := rp.readKey(t)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			msg := pickerKeyMessage{key: key, stopCh: make(chan bool)}
+			keyCh <- msg
+			if stop := <-msg.stopCh; stop {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(resourcePickerRedrawInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = t.Close()
+			return
+
+		case err := <-errCh:
+			st.Dispatch(store.ErrorAction{Error: err})
+			return
+
+		case msg := <-keyCh:
+			done := rp.handleKey(ctx, st, msg.key)
+			msg.stopCh <- done
+			if done {
+				p.printMenu()
+				p.listen(ctx, st, t)
+				return
+			}
+
+		case <-ticker.C:
+			if !p.isEnabled(st) {
+				// The terminal left prompt mode (e.g. `l` switched to
+				// stream mode), so give up the TTY like listen() does.
+				_ = t.Close()
+				return
+			}
+			rp.refresh(st)
+			rp.render()
+		}
+	}
+}
+
 type runeMessage struct {
 	rune rune
 
@@ -0,0 +1,360 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tilt-dev/tilt/internal/store"
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+// historyFileName is where resource-picker search queries are persisted across
+// sessions, mirroring the way shells and REPLs (bash, the geth JS console,
+// etc.) keep a `~/.foo_history` file.
+const historyFileName = ".tilt_history"
+
+// resourcePicker is the `r` mode of the prompt: instead of the fixed
+// s/h/space/e menu, it lists every manifest in the engine state and lets
+// the user navigate with the arrow keys, narrow the list with `/`, and act
+// on the highlighted resource.
+type resourcePicker struct {
+	prompt *TerminalPrompt
+
+	names    []model.ManifestName
+	filtered []model.ManifestName
+	cursor   int
+
+	// searching is true while `/` search input is being collected. It's a
+	// separate flag from query != "" so that an empty in-progress search
+	// (just pressed `/`, haven't typed anything yet) still routes
+	// subsequent keys to handleSearchKey instead of the top-level bindings.
+	searching bool
+	query     string
+
+	history    []string
+	historyIdx int
+}
+
+func newResourcePicker(p *TerminalPrompt) *resourcePicker {
+	return &resourcePicker{prompt: p, history: loadHistory()}
+}
+
+func loadHistory() []string {
+	path := historyPath()
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = f.Close() }()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func appendHistory(query string) {
+	path := historyPath()
+	if path == "" || query == "" {
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	_, _ = fmt.Fprintln(f, query)
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, historyFileName)
+}
+
+// refresh re-reads the manifest list from the store. Called on entry to the
+// picker, after each handled key, and on every resourcePickerRedrawInterval
+// tick, so the list stays live as builds complete.
+func (rp *resourcePicker) refresh(st store.RStore) {
+	state := st.RLockState()
+	names := make([]model.ManifestName, 0, len(state.ManifestTargets))
+	for _, mt := range state.ManifestTargets {
+		names = append(names, mt.Manifest.Name)
+	}
+	st.RUnlockState()
+
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	rp.names = names
+	rp.applyFilter()
+}
+
+// applyFilter recomputes rp.filtered from rp.names and rp.query. It always
+// writes into a freshly-allocated slice rather than reusing rp.names'
+// backing array, since rp.filtered is a strict subset of rp.names and
+// appending into a reused array would silently overwrite entries that
+// didn't match the filter.
+func (rp *resourcePicker) applyFilter() {
+	if rp.query == "" {
+		rp.filtered = append([]model.ManifestName(nil), rp.names...)
+	} else {
+		filtered := make([]model.ManifestName, 0, len(rp.names))
+		for _, n := range rp.names {
+			if strings.Contains(string(n), rp.query) {
+				filtered = append(filtered, n)
+			}
+		}
+		rp.filtered = filtered
+	}
+
+	if rp.cursor >= len(rp.filtered) {
+		rp.cursor = len(rp.filtered) - 1
+	}
+	if rp.cursor < 0 {
+		rp.cursor = 0
+	}
+}
+
+// FocusResourceLogsAction names the resource whose logs the user asked to
+// stream. It is dispatched alongside SwitchTerminalModeAction when the user
+// picks `l` on a resource in the picker.
+//
+// NOTE: this only defines and dispatches the action. No reducer or
+// log-streaming code reads it, so the stream is not narrowed: `l` streams
+// every resource's logs, the same as the top-level `s` binding.
+type FocusResourceLogsAction struct {
+	Name model.ManifestName
+}
+
+func (FocusResourceLogsAction) Action() {}
+
+// endpointForResource looks up the first endpoint link Tilt knows about for
+// a resource, the same data the web UI's resource list links out to. It
+// returns false if the resource has no endpoints (e.g. it's not a server,
+// or hasn't reported a runtime endpoint yet).
+func endpointForResource(st store.RStore, name model.ManifestName) (string, bool) {
+	state := st.RLockState()
+	defer st.RUnlockState()
+
+	for _, mt := range state.ManifestTargets {
+		if mt.Manifest.Name != name {
+			continue
+		}
+
+		links := store.ManifestTargetEndpoints(mt)
+		if len(links) == 0 {
+			return "", false
+		}
+		return fmt.Sprint(links[0].URL), true
+	}
+	return "", false
+}
+
+func (rp *resourcePicker) selected() (model.ManifestName, bool) {
+	if rp.cursor < 0 || rp.cursor >= len(rp.filtered) {
+		return "", false
+	}
+	return rp.filtered[rp.cursor], true
+}
+
+// render redraws the resource list and the currently active search query,
+// if any.
+func (rp *resourcePicker) render() {
+	stdout := rp.prompt.stdout
+	_, _ = fmt.Fprintf(stdout, "\nResources")
+	if rp.searching {
+		_, _ = fmt.Fprintf(stdout, " (search: %s)", rp.query)
+	}
+	_, _ = fmt.Fprintf(stdout, ":\n")
+
+	if len(rp.filtered) == 0 {
+		_, _ = fmt.Fprintf(stdout, "  (no matches)\n")
+	}
+	for i, n := range rp.filtered {
+		cursor := " "
+		if i == rp.cursor {
+			cursor = ">"
+		}
+		_, _ = fmt.Fprintf(stdout, "%s %s\n", cursor, n)
+	}
+	_, _ = fmt.Fprintf(stdout, "(up/down) navigate  (/) search  (b) rebuild  (l) stream logs  (o) open endpoint  (x) toggle enabled  (q) back\n")
+}
+
+// handleKey processes a single input event in the picker. It returns true
+// when the picker should exit back to the top-level menu.
+func (rp *resourcePicker) handleKey(ctx context.Context, st store.RStore, key pickerKey) bool {
+	if rp.searching {
+		return rp.handleSearchKey(key)
+	}
+
+	switch key.rune {
+	case 'q':
+		return true
+
+	case '/':
+		rp.searching = true
+		rp.historyIdx = len(rp.history)
+		rp.render()
+		return false
+
+	case pickerUp:
+		if rp.cursor > 0 {
+			rp.cursor--
+		}
+	case pickerDown:
+		if rp.cursor < len(rp.filtered)-1 {
+			rp.cursor++
+		}
+
+	case 'b':
+		if n, ok := rp.selected(); ok {
+			st.Dispatch(store.AppendToTriggerQueueAction{
+				Name:   n,
+				Reason: model.BuildReasonFlagTriggerWeb,
+			})
+			_, _ = fmt.Fprintf(rp.prompt.stdout, "Queued rebuild: %s\n", n)
+		}
+
+	case 'l':
+		if n, ok := rp.selected(); ok {
+			// FocusResourceLogsAction records n as the resource to stream;
+			// nothing handles it, so the stream still shows every
+			// resource's logs, same as the top-level `s` binding.
+			st.Dispatch(FocusResourceLogsAction{Name: n})
+			st.Dispatch(SwitchTerminalModeAction{Mode: store.TerminalModeStream})
+			_, _ = fmt.Fprintf(rp.prompt.stdout, "Streaming logs for: %s\n", n)
+		}
+
+	case 'o':
+		if n, ok := rp.selected(); ok {
+			url, ok := endpointForResource(st, n)
+			if !ok {
+				_, _ = fmt.Fprintf(rp.prompt.stdout, "No endpoint for: %s\n", n)
+				break
+			}
+
+			_, _ = fmt.Fprintf(rp.prompt.stdout, "Opening endpoint for %s: %s\n", n, url)
+			if err := rp.prompt.openURL(url); err != nil {
+				_, _ = fmt.Fprintf(rp.prompt.stdout, "Error: %v\n", err)
+			}
+		}
+
+	case 'x':
+		if n, ok := rp.selected(); ok {
+			st.Dispatch(store.ToggleEnabledAction{Name: n})
+			_, _ = fmt.Fprintf(rp.prompt.stdout, "Toggled enabled: %s\n", n)
+		}
+	}
+
+	rp.refresh(st)
+	rp.render()
+	return false
+}
+
+// handleSearchKey handles a keypress while `/` search input is being
+// collected. Up/down recall previous queries from rp.history, the same way
+// a shell's line editor would.
+func (rp *resourcePicker) handleSearchKey(key pickerKey) bool {
+	switch {
+	case key.rune == '\n' || key.rune == '\r':
+		if rp.query != "" {
+			appendHistory(rp.query)
+			rp.history = append(rp.history, rp.query)
+		}
+		rp.searching = false
+
+	case key.rune == pickerEsc:
+		rp.query = ""
+		rp.searching = false
+
+	case key.rune == pickerUp:
+		if rp.historyIdx > 0 {
+			rp.historyIdx--
+			rp.query = rp.history[rp.historyIdx]
+		}
+
+	case key.rune == pickerDown:
+		if rp.historyIdx < len(rp.history)-1 {
+			rp.historyIdx++
+			rp.query = rp.history[rp.historyIdx]
+		} else {
+			rp.historyIdx = len(rp.history)
+			rp.query = ""
+		}
+
+	case key.rune == '\b' || key.rune == 127:
+		if len(rp.query) > 0 {
+			rp.query = rp.query[:len(rp.query)-1]
+		}
+
+	default:
+		rp.query += string(key.rune)
+	}
+
+	rp.applyFilter()
+	rp.render()
+	return false
+}
+
+// pickerKey is a single input event fed to the picker. Arrow keys arrive
+// from the TTY as multi-rune escape sequences (ESC [ A/B); readKey()
+// collapses those into the pickerUp/pickerDown sentinels before handing
+// them to the picker so it doesn't need to know about terminal escape
+// codes.
+type pickerKey struct {
+	rune rune
+}
+
+const (
+	pickerUp   = rune(-1)
+	pickerDown = rune(-2)
+	pickerEsc  = rune(-3)
+)
+
+// readKey reads one input event from t, collapsing `ESC [ A`/`ESC [ B`
+// arrow-key escape sequences into pickerUp/pickerDown. Any other escape
+// sequence is reported as a bare pickerEsc.
+func (rp *resourcePicker) readKey(t TerminalInput) (pickerKey, error) {
+	r, err := t.ReadRune()
+	if err != nil {
+		return pickerKey{}, err
+	}
+	if r != '\x1b' {
+		return pickerKey{rune: r}, nil
+	}
+
+	r2, err := t.ReadRune()
+	if err != nil || r2 != '[' {
+		return pickerKey{rune: pickerEsc}, nil
+	}
+
+	r3, err := t.ReadRune()
+	if err != nil {
+		return pickerKey{rune: pickerEsc}, nil
+	}
+
+	switch r3 {
+	case 'A':
+		return pickerKey{rune: pickerUp}, nil
+	case 'B':
+		return pickerKey{rune: pickerDown}, nil
+	default:
+		return pickerKey{rune: pickerEsc}, nil
+	}
+}
@@ -0,0 +1,67 @@
+package prompt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tilt-dev/tilt/pkg/model"
+)
+
+func newTestResourcePicker() *resourcePicker {
+	return &resourcePicker{prompt: &TerminalPrompt{stdout: &bytes.Buffer{}}}
+}
+
+func TestApplyFilterDoesNotMutateNames(t *testing.T) {
+	rp := &resourcePicker{
+		names: []model.ManifestName{"a", "b", "c"},
+	}
+
+	rp.query = "c"
+	rp.applyFilter()
+
+	assert.Equal(t, []model.ManifestName{"c"}, rp.filtered)
+	require.Equal(t, []model.ManifestName{"a", "b", "c"}, rp.names,
+		"applyFilter must not mutate rp.names via a shared backing array")
+}
+
+func TestApplyFilterEmptyQueryReturnsAllNames(t *testing.T) {
+	rp := &resourcePicker{
+		names: []model.ManifestName{"a", "b"},
+	}
+
+	rp.applyFilter()
+
+	assert.Equal(t, []model.ManifestName{"a", "b"}, rp.filtered)
+}
+
+func TestApplyFilterClampsCursor(t *testing.T) {
+	rp := &resourcePicker{
+		names:  []model.ManifestName{"a", "b", "c"},
+		cursor: 2,
+	}
+
+	rp.query = "a"
+	rp.applyFilter()
+
+	assert.Equal(t, 0, rp.cursor)
+}
+
+func TestHandleSearchKeyRecallsHistory(t *testing.T) {
+	rp := newTestResourcePicker()
+	rp.names = []model.ManifestName{"frontend", "backend"}
+	rp.history = []string{"front", "back"}
+	rp.historyIdx = 2
+	rp.searching = true
+
+	rp.handleSearchKey(pickerKey{rune: pickerUp})
+	assert.Equal(t, "back", rp.query)
+
+	rp.handleSearchKey(pickerKey{rune: pickerUp})
+	assert.Equal(t, "front", rp.query)
+
+	rp.handleSearchKey(pickerKey{rune: pickerDown})
+	assert.Equal(t, "back", rp.query)
+}
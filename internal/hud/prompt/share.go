@@ -0,0 +1,186 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/tilt-dev/tilt/internal/store"
+)
+
+// FrameType identifies the payload of a Frame exchanged with a shared
+// prompt viewer, modeled on the tty-share sender/receiver protocol.
+type FrameType string
+
+const (
+	// FrameWrite carries a chunk of prompt output.
+	FrameWrite FrameType = "Write"
+	// FrameWinSize is currently unused (the prompt doesn't resize), but is
+	// part of the protocol so viewers can render a terminal-shaped box.
+	FrameWinSize FrameType = "WinSize"
+	// FrameKey carries a single-rune command from a write-capable viewer.
+	FrameKey FrameType = "Key"
+)
+
+// Frame is the wire format between SharePrompt and remote viewers.
+type Frame struct {
+	ID   int       `json:"id"`
+	Type FrameType `json:"type"`
+	Data string    `json:"data"`
+}
+
+var upgrader = websocket.Upgrader{
+	// The prompt is only ever served from Tilt's own web server, so we
+	// don't need to be picky about Origin here.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// SharePrompt multiplexes a TerminalPrompt's greeting/menu and log output
+// to any number of remote viewers over WebSocket, so a teammate can watch
+// (or, with the write token, drive) a Tilt session without SSH access.
+//
+// The local prompt is the "sender": everything written to its stdout is
+// fanned out to connected clients. Write-capable clients can send back the
+// same single-rune commands (s, h, space) the local TTY accepts.
+type SharePrompt struct {
+	prompt *TerminalPrompt
+	token  string
+
+	mu      sync.Mutex
+	nextID  int
+	clients map[*shareClient]bool
+}
+
+// NewSharePrompt wraps prompt so its output is mirrored to WebSocket
+// viewers. It should be constructed once, alongside the TerminalPrompt
+// itself, and registered as both an http.Handler (mounted on the Tilt web
+// server) and a store.Subscriber.
+func NewSharePrompt(prompt *TerminalPrompt, token string) *SharePrompt {
+	s := &SharePrompt{
+		prompt:  prompt,
+		token:   token,
+		clients: make(map[*shareClient]bool),
+	}
+	prompt.stdout = io.MultiWriter(prompt.stdout, s)
+	return s
+}
+
+type shareClient struct {
+	conn      *websocket.Conn
+	writeable bool
+
+	mu sync.Mutex
+}
+
+func (c *shareClient) send(f Frame) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(f)
+}
+
+// Write implements io.Writer so SharePrompt can be wrapped around the
+// prompt's stdout with io.MultiWriter: everything the prompt prints gets
+// broadcast to connected viewers too.
+func (s *SharePrompt) Write(b []byte) (int, error) {
+	s.broadcast(FrameWrite, string(b))
+	return len(b), nil
+}
+
+func (s *SharePrompt) broadcast(t FrameType, data string) {
+	s.mu.Lock()
+	s.nextID++
+	f := Frame{ID: s.nextID, Type: t, Data: data}
+	clients := make([]*shareClient, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		if err := c.send(f); err != nil {
+			s.removeClient(c)
+		}
+	}
+}
+
+func (s *SharePrompt) addClient(c *shareClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[c] = true
+}
+
+func (s *SharePrompt) removeClient(c *shareClient) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients, c)
+	_ = c.conn.Close()
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams prompt output
+// to it. A client is write-capable (and may send Key frames back) only if
+// it supplies the token passed to NewSharePrompt as a `token` query
+// parameter. This package does not build a shareable link containing the
+// token, and model.WebURL does not carry it.
+func (s *SharePrompt) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	client := &shareClient{
+		conn:      conn,
+		writeable: s.token != "" && r.URL.Query().Get("token") == s.token,
+	}
+	s.addClient(client)
+	defer s.removeClient(client)
+
+	for {
+		var f Frame
+		if err := conn.ReadJSON(&f); err != nil {
+			return
+		}
+		if !client.writeable || f.Type != FrameKey {
+			continue
+		}
+		for _, r := range f.Data {
+			s.prompt.handleRemoteKey(r)
+		}
+	}
+}
+
+// handleRemoteKey applies the subset of the local single-rune commands
+// that make sense for a remote viewer to trigger, by going through the same
+// commandsByRune registry the interactive loop uses (see commands.go) —
+// rather than a third copy of the s/h/space switch — so the two dispatch
+// paths can't drift. `e` (shell out) and `r` (resource picker) aren't in
+// the registry, so they're simply unreachable here: remote viewers can only
+// trigger the top-level commands, never open the resource picker or shell
+// out. (They do still see the picker's output, since it goes to p.stdout.)
+func (p *TerminalPrompt) handleRemoteKey(r rune) {
+	st := p.lastStoreForDispatch()
+	if st == nil {
+		return
+	}
+
+	cmd, ok := commandsByRune[r]
+	if !ok {
+		return
+	}
+
+	if err := cmd.Run(context.Background(), p, st, nil); err != nil {
+		_, _ = fmt.Fprintf(p.stdout, "Error: %v\n", err)
+	}
+}
+
+// OnChange keeps p.lastStore current so remote viewers connected via
+// SharePrompt can dispatch actions between TerminalPrompt.OnChange calls.
+func (s *SharePrompt) OnChange(ctx context.Context, st store.RStore) {
+	s.prompt.setLastStore(st)
+}
+
+var _ store.Subscriber = &SharePrompt{}
+var _ http.Handler = &SharePrompt{}